@@ -2,11 +2,15 @@
 package version
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime/debug"
 	"strings"
+	"sync"
+	"time"
 )
 
 // These variables are set at build time via ldflags in cmd package.
@@ -16,13 +20,43 @@ var (
 	Commit = ""
 )
 
+// Tuning knobs for CheckStaleBinary. Exported so callers (and tests) can
+// adjust them. StaleBinaryCheckTimeout falls back to the documented default
+// whenever it's <= 0, since a non-positive timeout isn't meaningful.
+// StaleBinaryCacheTTL is different: 0 is a valid, meaningful value meaning
+// "don't cache, always re-check" (only a negative TTL falls back to the
+// default).
+var (
+	// StaleBinaryCheckTimeout bounds how long the git invocations inside
+	// CheckStaleBinary are allowed to run before being treated as a
+	// failure, so a hung or slow git never blocks the CLI.
+	StaleBinaryCheckTimeout = 500 * time.Millisecond
+
+	// StaleBinaryCacheTTL is how long a CheckStaleBinary result is reused
+	// for a given repoDir before the underlying git commands are re-run.
+	// Set to 0 to disable caching entirely.
+	StaleBinaryCacheTTL = 30 * time.Second
+)
+
+// staleBinaryCache memoizes CheckStaleBinary results per repoDir so that
+// repeated CLI invocations within the TTL don't re-fork git.
+var staleBinaryCache sync.Map // repoDir -> staleBinaryCacheEntry
+
+type staleBinaryCacheEntry struct {
+	info     *StaleBinaryInfo
+	cachedAt time.Time
+}
+
 // StaleBinaryInfo contains information about binary staleness.
 type StaleBinaryInfo struct {
-	IsStale       bool   // True if binary commit doesn't match repo HEAD
-	BinaryCommit  string // Commit hash the binary was built from
-	RepoCommit    string // Current repo HEAD commit
-	CommitsBehind int    // Number of commits binary is behind (0 if unknown)
-	Error         error  // Any error encountered during check
+	IsStale       bool      // True if the binary is out of date relative to the repo
+	BinaryCommit  string    // Commit hash the binary was built from
+	RepoCommit    string    // Current repo HEAD commit
+	CommitsBehind int       // Commits HEAD is ahead of BinaryCommit (0 if unknown)
+	CommitsAhead  int       // Commits HEAD is ahead of the merge-base, when BinaryCommit isn't an ancestor of HEAD (e.g. after a rebase)
+	Modified      bool      // True if the binary was built from a dirty tree (vcs.modified=true)
+	BuildTime     time.Time // vcs.time from the embedded build info, if available
+	Error         error     // Any error encountered during check
 }
 
 // resolveCommitHash gets the commit hash from build info or the Commit variable.
@@ -42,6 +76,32 @@ func resolveCommitHash() string {
 	return ""
 }
 
+// vcsInfoFn reads the binary's embedded build info; overridable by tests,
+// which can't control the real vcs.modified/vcs.time settings of the test
+// binary itself.
+var vcsInfoFn = buildInfoVCS
+
+// buildInfoVCS returns the vcs.modified and vcs.time settings recorded in
+// the binary's embedded build info, if any.
+func buildInfoVCS() (modified bool, buildTime time.Time) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return false, time.Time{}
+	}
+
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.modified":
+			modified = setting.Value == "true"
+		case "vcs.time":
+			if t, err := time.Parse(time.RFC3339, setting.Value); err == nil {
+				buildTime = t
+			}
+		}
+	}
+	return modified, buildTime
+}
+
 // ShortCommit returns first 12 characters of a hash.
 func ShortCommit(hash string) string {
 	if len(hash) > 12 {
@@ -64,42 +124,118 @@ func commitsMatch(a, b string) bool {
 	return strings.HasPrefix(a, b[:minLen]) || strings.HasPrefix(b, a[:minLen])
 }
 
+// runGit runs git with args in dir, bounded by ctx, and returns its trimmed
+// stdout. If ctx expires before the command completes, the returned error
+// is ctx.Err().
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// parseCount parses a git rev-list --count output, returning 0 on failure.
+func parseCount(s string) int {
+	var n int
+	if _, err := fmt.Sscanf(s, "%d", &n); err != nil {
+		return 0
+	}
+	return n
+}
+
 // CheckStaleBinary compares the binary's embedded commit with the repo HEAD.
 // It returns staleness info including whether the binary needs rebuilding.
-// This check is designed to be fast and non-blocking - errors are captured
-// but don't interrupt normal operation.
+// This check is designed to be fast and non-blocking: git invocations are
+// bounded by StaleBinaryCheckTimeout, and results are cached per repoDir for
+// StaleBinaryCacheTTL so repeated CLI commands don't re-fork git.
 func CheckStaleBinary(repoDir string) *StaleBinaryInfo {
+	ttl := cacheTTL()
+	if ttl <= 0 {
+		return checkStaleBinaryUncached(repoDir)
+	}
+
+	if cached, ok := staleBinaryCache.Load(repoDir); ok {
+		entry := cached.(staleBinaryCacheEntry)
+		if time.Since(entry.cachedAt) < ttl {
+			return entry.info
+		}
+	}
+
+	info := checkStaleBinaryUncached(repoDir)
+	staleBinaryCache.Store(repoDir, staleBinaryCacheEntry{info: info, cachedAt: time.Now()})
+	return info
+}
+
+// cacheTTL returns the effective cache TTL: the configured
+// StaleBinaryCacheTTL, or the default if it's negative. Zero is passed
+// through unchanged, since it means "disable caching" (see CheckStaleBinary).
+func cacheTTL() time.Duration {
+	if StaleBinaryCacheTTL < 0 {
+		return 30 * time.Second
+	}
+	return StaleBinaryCacheTTL
+}
+
+func checkTimeout() time.Duration {
+	if StaleBinaryCheckTimeout <= 0 {
+		return 500 * time.Millisecond
+	}
+	return StaleBinaryCheckTimeout
+}
+
+func checkStaleBinaryUncached(repoDir string) *StaleBinaryInfo {
 	info := &StaleBinaryInfo{}
 
-	// Get binary commit
 	info.BinaryCommit = resolveCommitHash()
+	info.Modified, info.BuildTime = vcsInfoFn()
 	if info.BinaryCommit == "" {
 		info.Error = fmt.Errorf("cannot determine binary commit (dev build?)")
 		return info
 	}
 
-	// Get repo HEAD
-	cmd := exec.Command("git", "rev-parse", "HEAD")
-	cmd.Dir = repoDir
-	output, err := cmd.Output()
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout())
+	defer cancel()
+
+	repoCommit, err := runGit(ctx, repoDir, "rev-parse", "HEAD")
 	if err != nil {
+		if ctx.Err() != nil {
+			return &StaleBinaryInfo{Error: ctx.Err()}
+		}
 		info.Error = fmt.Errorf("cannot get repo HEAD: %w", err)
 		return info
 	}
-	info.RepoCommit = strings.TrimSpace(string(output))
+	info.RepoCommit = repoCommit
 
-	// Compare commits using prefix matching (handles short vs full hash)
-	// Use the shorter of the two commit lengths for comparison
-	if !commitsMatch(info.BinaryCommit, info.RepoCommit) {
+	if info.Modified {
 		info.IsStale = true
+	}
 
-		// Try to count commits between binary and HEAD
-		countCmd := exec.Command("git", "rev-list", "--count", info.BinaryCommit+"..HEAD")
-		countCmd.Dir = repoDir
-		if countOutput, err := countCmd.Output(); err == nil {
-			if count, parseErr := fmt.Sscanf(strings.TrimSpace(string(countOutput)), "%d", &info.CommitsBehind); parseErr != nil || count != 1 {
-				info.CommitsBehind = 0
-			}
+	if commitsMatch(info.BinaryCommit, info.RepoCommit) {
+		return info
+	}
+
+	info.IsStale = true
+
+	if _, err := runGit(ctx, repoDir, "merge-base", "--is-ancestor", info.BinaryCommit, "HEAD"); err == nil {
+		// BinaryCommit is an ancestor of HEAD: the common case of a clean
+		// fast-forward, so "commits behind" is just commits since the build.
+		if countOutput, err := runGit(ctx, repoDir, "rev-list", "--count", info.BinaryCommit+"..HEAD"); err == nil {
+			info.CommitsBehind = parseCount(countOutput)
+		}
+		return info
+	}
+
+	// BinaryCommit isn't reachable from HEAD (e.g. the user rebased).
+	// Report how far HEAD has diverged from their common ancestor instead.
+	if mergeBase, err := runGit(ctx, repoDir, "merge-base", info.BinaryCommit, "HEAD"); err == nil && mergeBase != "" {
+		if countOutput, err := runGit(ctx, repoDir, "rev-list", "--count", mergeBase+"..HEAD"); err == nil {
+			info.CommitsAhead = parseCount(countOutput)
 		}
 	}
 
@@ -154,8 +290,8 @@ func isGitRepo(dir string) bool {
 // hasGastownMarker checks if a directory looks like the gastown repo.
 func hasGastownMarker(dir string) bool {
 	// Check for cmd/gt directory which is unique to gastown
-	cmd := exec.Command("test", "-d", dir+"/cmd/gt")
-	return cmd.Run() == nil
+	st, err := os.Stat(filepath.Join(dir, "cmd", "gt"))
+	return err == nil && st.IsDir()
 }
 
 // SetCommit allows the cmd package to pass in the build-time commit.
@@ -0,0 +1,219 @@
+package version
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gitFixture creates a temporary git repo with one commit and returns its
+// path and HEAD commit hash.
+func gitFixture(t *testing.T) (dir, head string) {
+	t.Helper()
+	dir = t.TempDir()
+	runGitCmd(t, dir, "init", "-q", "-b", "main")
+	runGitCmd(t, dir, "config", "user.name", "test")
+	runGitCmd(t, dir, "config", "user.email", "test@example.com")
+	writeAndCommit(t, dir, "a.txt", "initial")
+
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("rev-parse HEAD: %v", err)
+	}
+	return dir, strings.TrimSpace(string(out))
+}
+
+func runGitCmd(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func writeAndCommit(t *testing.T, dir, file, message string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(message), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	runGitCmd(t, dir, "add", file)
+	runGitCmd(t, dir, "commit", "-q", "-m", message)
+}
+
+// withTestTuning resets the package's overridable knobs and caches around a
+// test, restoring them afterward so tests don't leak state into each other.
+func withTestTuning(t *testing.T) {
+	t.Helper()
+	origTimeout, origTTL, origVCSInfo := StaleBinaryCheckTimeout, StaleBinaryCacheTTL, vcsInfoFn
+	t.Cleanup(func() {
+		StaleBinaryCheckTimeout = origTimeout
+		StaleBinaryCacheTTL = origTTL
+		vcsInfoFn = origVCSInfo
+		staleBinaryCache = sync.Map{}
+		SetCommit("")
+	})
+	StaleBinaryCacheTTL = 0 // disable caching unless a test opts in
+	staleBinaryCache = sync.Map{}
+}
+
+func TestCheckStaleBinaryMatchingCommit(t *testing.T) {
+	withTestTuning(t)
+	dir, head := gitFixture(t)
+	SetCommit(head)
+
+	info := CheckStaleBinary(dir)
+	if info.Error != nil {
+		t.Fatalf("unexpected error: %v", info.Error)
+	}
+	if info.IsStale {
+		t.Fatalf("expected IsStale=false when binary commit matches HEAD, got %+v", info)
+	}
+}
+
+func TestCheckStaleBinaryBehindHEAD(t *testing.T) {
+	withTestTuning(t)
+	dir, head := gitFixture(t)
+	SetCommit(head)
+
+	writeAndCommit(t, dir, "b.txt", "second")
+
+	info := CheckStaleBinary(dir)
+	if info.Error != nil {
+		t.Fatalf("unexpected error: %v", info.Error)
+	}
+	if !info.IsStale {
+		t.Fatal("expected IsStale=true when HEAD has advanced")
+	}
+	if info.CommitsBehind != 1 {
+		t.Fatalf("CommitsBehind = %d, want 1", info.CommitsBehind)
+	}
+}
+
+func TestCheckStaleBinaryUnreachableCommit(t *testing.T) {
+	withTestTuning(t)
+	dir, head := gitFixture(t)
+	writeAndCommit(t, dir, "b.txt", "second")
+
+	// A rebase: the binary's commit ("head", the first commit) is no
+	// longer the parent of HEAD in a rewritten history. Simulate that by
+	// pointing the binary at a commit that exists but isn't an ancestor of
+	// the repo's current HEAD, via a detached side branch.
+	runGitCmd(t, dir, "checkout", "-q", head)
+	writeAndCommit(t, dir, "c.txt", "side")
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		t.Fatalf("rev-parse HEAD: %v", err)
+	}
+	sideCommit := strings.TrimSpace(string(out))
+	runGitCmd(t, dir, "checkout", "-q", "main")
+
+	SetCommit(sideCommit)
+
+	info := CheckStaleBinary(dir)
+	if info.Error != nil {
+		t.Fatalf("unexpected error: %v", info.Error)
+	}
+	if !info.IsStale {
+		t.Fatal("expected IsStale=true when binary commit is unreachable from HEAD")
+	}
+	if info.CommitsAhead != 1 {
+		t.Fatalf("CommitsAhead = %d, want 1", info.CommitsAhead)
+	}
+}
+
+func TestCheckStaleBinaryDirtyBuildIsStale(t *testing.T) {
+	withTestTuning(t)
+	dir, head := gitFixture(t)
+	SetCommit(head)
+	vcsInfoFn = func() (bool, time.Time) { return true, time.Time{} }
+
+	info := CheckStaleBinary(dir)
+	if info.Error != nil {
+		t.Fatalf("unexpected error: %v", info.Error)
+	}
+	if !info.IsStale {
+		t.Fatal("expected a dirty build (vcs.modified=true) to be reported stale even with a matching commit")
+	}
+}
+
+func TestCheckStaleBinaryCaches(t *testing.T) {
+	withTestTuning(t)
+	StaleBinaryCacheTTL = time.Hour
+
+	dir, head := gitFixture(t)
+	SetCommit(head)
+
+	first := CheckStaleBinary(dir)
+
+	// Remove the repo out from under the cache; if CheckStaleBinary
+	// re-ran git it would now fail.
+	if err := os.RemoveAll(filepath.Join(dir, ".git")); err != nil {
+		t.Fatalf("RemoveAll: %v", err)
+	}
+
+	second := CheckStaleBinary(dir)
+	if second != first {
+		t.Fatal("expected cached result to be reused within the TTL")
+	}
+}
+
+func TestCheckStaleBinaryZeroTTLDisablesCaching(t *testing.T) {
+	withTestTuning(t) // StaleBinaryCacheTTL = 0
+
+	dir, head := gitFixture(t)
+	SetCommit(head)
+
+	first := CheckStaleBinary(dir)
+	if first.Error != nil {
+		t.Fatalf("unexpected error: %v", first.Error)
+	}
+
+	// With caching disabled, removing the repo should surface on the very
+	// next call rather than returning a stale cached result.
+	if err := os.RemoveAll(filepath.Join(dir, ".git")); err != nil {
+		t.Fatalf("RemoveAll: %v", err)
+	}
+
+	second := CheckStaleBinary(dir)
+	if second == first {
+		t.Fatal("expected a fresh result with caching disabled, got the cached one")
+	}
+	if second.Error == nil {
+		t.Fatal("expected an error after removing the repo with caching disabled")
+	}
+}
+
+func TestCheckStaleBinaryTimeout(t *testing.T) {
+	withTestTuning(t)
+	dir, head := gitFixture(t)
+	SetCommit(head)
+
+	StaleBinaryCheckTimeout = time.Nanosecond
+	info := CheckStaleBinary(dir)
+	if info.Error == nil {
+		t.Fatal("expected a timeout error with a near-zero deadline")
+	}
+}
+
+func TestHasGastownMarker(t *testing.T) {
+	dir := t.TempDir()
+	if hasGastownMarker(dir) {
+		t.Fatal("expected no marker in an empty directory")
+	}
+
+	if err := os.MkdirAll(filepath.Join(dir, "cmd", "gt"), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if !hasGastownMarker(dir) {
+		t.Fatal("expected marker to be found once cmd/gt exists")
+	}
+}
@@ -0,0 +1,353 @@
+package witness
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// socketPath returns the path to the witness's local control socket.
+func (m *Manager) socketPath() string {
+	return filepath.Join(m.rig.Path, ".gastown", "witness.sock")
+}
+
+// Event is a single witness occurrence, published internally over the
+// witness's event bus and streamed out over /events and (once written) the
+// JSONL event log.
+type Event struct {
+	Time    time.Time `json:"ts"`
+	Rig     string    `json:"rig"`
+	Polecat string    `json:"polecat,omitempty"`
+	Kind    string    `json:"kind"`
+	Reason  string    `json:"reason,omitempty"`
+
+	// MolID is the molecule/task ID the event pertains to, when one was in
+	// play (e.g. a nudge sent while a polecat was assigned work). Empty for
+	// events that aren't associated with a specific molecule.
+	MolID string `json:"mol_id,omitempty"`
+}
+
+// Event kinds.
+const (
+	EventCheck    = "check"
+	EventNudge    = "nudge"
+	EventEscalate = "escalate"
+	EventPause    = "pause"
+	EventResume   = "resume"
+	EventStart    = "start"
+	EventStop     = "stop"
+	EventStaleBin = "stale-binary"
+)
+
+// publishEvent records an event to the JSONL event log and Prometheus
+// metrics, then fans it out to every live subscriber (e.g. /events SSE
+// clients). Subscribers that aren't keeping up have the event dropped
+// rather than blocking the health-check loop.
+func (m *Manager) publishEvent(e Event) {
+	e.Rig = m.rig.Name
+
+	if m.metrics != nil {
+		m.metrics.record(e)
+	}
+	if err := m.getEventLog().Write(e); err != nil {
+		fmt.Printf("witness: writing event log: %v\n", err)
+	}
+
+	m.eventMu.Lock()
+	defer m.eventMu.Unlock()
+	for ch := range m.eventSubs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// subscribeEvents registers a new subscriber and returns its channel plus a
+// function to unregister it.
+func (m *Manager) subscribeEvents() (<-chan Event, func()) {
+	ch := make(chan Event, 32)
+	m.eventMu.Lock()
+	if m.eventSubs == nil {
+		m.eventSubs = make(map[chan Event]struct{})
+	}
+	m.eventSubs[ch] = struct{}{}
+	m.eventMu.Unlock()
+
+	cancel := func() {
+		m.eventMu.Lock()
+		delete(m.eventSubs, ch)
+		m.eventMu.Unlock()
+		close(ch)
+	}
+	return ch, cancel
+}
+
+// startAPIServer binds the local control socket and starts serving it in
+// the background. It returns a stop function that closes the listener and
+// shuts the server down.
+func (m *Manager) startAPIServer(w *Witness) (func(), error) {
+	sockPath := m.socketPath()
+	os.Remove(sockPath) // stale socket from a previous (crashed) run
+
+	if err := os.MkdirAll(filepath.Dir(sockPath), 0755); err != nil {
+		return nil, err
+	}
+
+	// Narrow the umask for the Listen call so the socket is created as
+	// 0600 from the instant it exists, rather than momentarily at the
+	// default (often world-connectable) mode before Chmod tightens it.
+	oldUmask := syscall.Umask(0177)
+	listener, err := net.Listen("unix", sockPath)
+	syscall.Umask(oldUmask)
+	if err != nil {
+		return nil, fmt.Errorf("binding witness control socket: %w", err)
+	}
+	if err := os.Chmod(sockPath, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("setting witness control socket permissions: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	m.registerAPIRoutes(mux, w)
+	srv := &http.Server{Handler: mux}
+
+	go func() {
+		if err := srv.Serve(listener); err != nil && err != http.ErrServerClosed {
+			fmt.Printf("witness: control socket server exited: %v\n", err)
+		}
+	}()
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+		os.Remove(sockPath)
+	}, nil
+}
+
+func (m *Manager) registerAPIRoutes(mux *http.ServeMux, w *Witness) {
+	mux.HandleFunc("/status", func(rw http.ResponseWriter, r *http.Request) {
+		m.stateMu.RLock()
+		defer m.stateMu.RUnlock()
+		writeJSON(rw, w)
+	})
+
+	mux.HandleFunc("/polecats", func(rw http.ResponseWriter, r *http.Request) {
+		m.stateMu.RLock()
+		defer m.stateMu.RUnlock()
+		writeJSON(rw, w.PolecatHealth)
+	})
+
+	mux.HandleFunc("/pause", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		m.stateMu.Lock()
+		w.State = StatePaused
+		err := m.saveState(w)
+		m.stateMu.Unlock()
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		m.publishEvent(Event{Time: m.clock(), Kind: EventPause})
+		writeJSON(rw, w)
+	})
+
+	mux.HandleFunc("/resume", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		m.stateMu.Lock()
+		w.State = StateRunning
+		err := m.saveState(w)
+		m.stateMu.Unlock()
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		m.publishEvent(Event{Time: m.clock(), Kind: EventResume})
+		writeJSON(rw, w)
+	})
+
+	mux.HandleFunc("/nudge/", func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		polecat := strings.TrimPrefix(r.URL.Path, "/nudge/")
+		if polecat == "" {
+			http.Error(rw, "missing polecat name", http.StatusBadRequest)
+			return
+		}
+
+		nudger := m.nudger
+		if nudger == nil {
+			nudger = LogNudger{}
+		}
+
+		m.beginWork()
+		err := nudger.Nudge(r.Context(), polecat, "manual nudge")
+		m.endWork()
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		m.stateMu.Lock()
+		if w.PolecatHealth == nil {
+			w.PolecatHealth = make(map[string]*PolecatHealth)
+		}
+		ph, ok := w.PolecatHealth[polecat]
+		if !ok {
+			ph = &PolecatHealth{}
+			w.PolecatHealth[polecat] = ph
+		}
+		now := m.clock()
+		ph.NudgeCount++
+		ph.LastNudgeAt = &now
+		w.Stats.TotalNudges++
+		w.Stats.TodayNudges++
+		err = m.saveState(w)
+		m.stateMu.Unlock()
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		m.publishEvent(Event{Time: now, Polecat: polecat, Kind: EventNudge, Reason: "manual nudge"})
+		writeJSON(rw, ph)
+	})
+
+	mux.HandleFunc("/idle", func(rw http.ResponseWriter, r *http.Request) {
+		if err := m.WaitIdle(r.Context()); err != nil {
+			http.Error(rw, err.Error(), http.StatusGatewayTimeout)
+			return
+		}
+		writeJSON(rw, map[string]bool{"idle": true})
+	})
+
+	mux.HandleFunc("/metrics", func(rw http.ResponseWriter, r *http.Request) {
+		rw.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		m.metrics.WriteTo(rw)
+	})
+
+	mux.HandleFunc("/events", func(rw http.ResponseWriter, r *http.Request) {
+		flusher, ok := rw.(http.Flusher)
+		if !ok {
+			http.Error(rw, "streaming unsupported", http.StatusInternalServerError)
+			return
+		}
+
+		ch, cancel := m.subscribeEvents()
+		defer cancel()
+
+		rw.Header().Set("Content-Type", "text/event-stream")
+		rw.Header().Set("Cache-Control", "no-cache")
+		rw.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		for {
+			select {
+			case ev, ok := <-ch:
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(ev)
+				if err != nil {
+					continue
+				}
+				fmt.Fprintf(rw, "data: %s\n\n", data)
+				flusher.Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+}
+
+func writeJSON(rw http.ResponseWriter, v interface{}) {
+	rw.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(rw).Encode(v)
+}
+
+// socketHTTPClient returns an http.Client that dials the witness's local
+// Unix-domain control socket. It dials once per request; retries are
+// done by socketRequest.
+func socketHTTPClient(sockPath string) *http.Client {
+	return &http.Client{
+		Timeout: 2 * time.Second,
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return net.Dial("unix", sockPath)
+			},
+		},
+	}
+}
+
+// socketRequest issues an HTTP request against the witness control socket,
+// retrying a few times with backoff before giving up (the daemon may be
+// mid-restart, or the socket stale from a crash).
+func socketRequest(sockPath, method, path string) (*http.Response, error) {
+	client := socketHTTPClient(sockPath)
+
+	var lastErr error
+	backoff := 50 * time.Millisecond
+	for attempt := 0; attempt < 3; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		req, err := http.NewRequest(method, "http://witness"+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := client.Do(req)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("dialing witness control socket %s: %w", sockPath, lastErr)
+}
+
+// StatusViaSocket fetches status from a running witness daemon over its
+// control socket. Callers should fall back to reading witness.json (via
+// Status) if this returns an error, e.g. because the daemon isn't running.
+func (m *Manager) StatusViaSocket() (*Witness, error) {
+	resp, err := socketRequest(m.socketPath(), http.MethodGet, "/status")
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("witness control socket returned %s", resp.Status)
+	}
+
+	var w Witness
+	if err := json.NewDecoder(resp.Body).Decode(&w); err != nil {
+		return nil, fmt.Errorf("decoding witness status: %w", err)
+	}
+	return &w, nil
+}
+
+// StatusPreferSocket returns the witness's status, preferring the live
+// control socket (so it reflects in-memory state like pause/resume) and
+// falling back to the on-disk witness.json if the socket isn't reachable.
+func (m *Manager) StatusPreferSocket() (*Witness, error) {
+	if w, err := m.StatusViaSocket(); err == nil {
+		return w, nil
+	}
+	return m.Status()
+}
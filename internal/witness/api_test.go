@@ -0,0 +1,164 @@
+package witness
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func newTestManagerWithAPI(t *testing.T) (*Manager, *Witness, func()) {
+	t.Helper()
+	dir := t.TempDir()
+	r := &rig.Rig{Path: dir, Name: "test-rig", Polecats: []string{"gus"}}
+	m := NewManager(r)
+
+	w := &Witness{RigName: "test-rig", State: StateRunning}
+	stop, err := m.startAPIServer(w)
+	if err != nil {
+		t.Fatalf("startAPIServer: %v", err)
+	}
+	return m, w, stop
+}
+
+func TestAPIStatusAndPauseResume(t *testing.T) {
+	m, w, stop := newTestManagerWithAPI(t)
+	defer stop()
+
+	resp, err := socketRequest(m.socketPath(), http.MethodGet, "/status")
+	if err != nil {
+		t.Fatalf("GET /status: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /status status = %d, want 200", resp.StatusCode)
+	}
+
+	resp, err = socketRequest(m.socketPath(), http.MethodPost, "/pause")
+	if err != nil {
+		t.Fatalf("POST /pause: %v", err)
+	}
+	resp.Body.Close()
+
+	m.stateMu.RLock()
+	state := w.State
+	m.stateMu.RUnlock()
+	if state != StatePaused {
+		t.Fatalf("State = %q, want %q", state, StatePaused)
+	}
+
+	resp, err = socketRequest(m.socketPath(), http.MethodPost, "/resume")
+	if err != nil {
+		t.Fatalf("POST /resume: %v", err)
+	}
+	resp.Body.Close()
+
+	m.stateMu.RLock()
+	state = w.State
+	m.stateMu.RUnlock()
+	if state != StateRunning {
+		t.Fatalf("State = %q, want %q", state, StateRunning)
+	}
+}
+
+func TestAPINudgeUpdatesHealth(t *testing.T) {
+	m, w, stop := newTestManagerWithAPI(t)
+	defer stop()
+
+	resp, err := socketRequest(m.socketPath(), http.MethodPost, "/nudge/gus")
+	if err != nil {
+		t.Fatalf("POST /nudge/gus: %v", err)
+	}
+	resp.Body.Close()
+
+	m.stateMu.RLock()
+	ph, ok := w.PolecatHealth["gus"]
+	m.stateMu.RUnlock()
+	if !ok {
+		t.Fatal("expected gus to have health entry after manual nudge")
+	}
+	if ph.NudgeCount != 1 {
+		t.Fatalf("NudgeCount = %d, want 1", ph.NudgeCount)
+	}
+}
+
+func TestAPIIdle(t *testing.T) {
+	m, _, stop := newTestManagerWithAPI(t)
+	defer stop()
+
+	resp, err := socketRequest(m.socketPath(), http.MethodGet, "/idle")
+	if err != nil {
+		t.Fatalf("GET /idle: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /idle status = %d, want 200", resp.StatusCode)
+	}
+}
+
+func TestAPIMetrics(t *testing.T) {
+	m, _, stop := newTestManagerWithAPI(t)
+	defer stop()
+
+	resp, err := socketRequest(m.socketPath(), http.MethodPost, "/nudge/gus")
+	if err != nil {
+		t.Fatalf("POST /nudge/gus: %v", err)
+	}
+	resp.Body.Close()
+
+	resp, err = socketRequest(m.socketPath(), http.MethodGet, "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics: %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading /metrics body: %v", err)
+	}
+	if !strings.Contains(string(body), `witness_nudges_total{polecat="gus"} 1`) {
+		t.Fatalf("expected /metrics to reflect the manual nudge, got:\n%s", body)
+	}
+}
+
+func TestAPIEventsStream(t *testing.T) {
+	m, _, stop := newTestManagerWithAPI(t)
+	defer stop()
+
+	client := socketHTTPClient(m.socketPath())
+	req, _ := http.NewRequest(http.MethodGet, "http://witness/events", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("GET /events: %v", err)
+	}
+	defer resp.Body.Close()
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		socketRequest(m.socketPath(), http.MethodPost, "/pause")
+	}()
+
+	reader := bufio.NewReader(resp.Body)
+	found := false
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			continue
+		}
+		if strings.Contains(line, `"kind":"pause"`) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Fatal("expected to observe a pause event over /events")
+	}
+}
@@ -0,0 +1,266 @@
+package witness
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// readyMsg is the prefix written to the readiness pipe once the daemon has
+// acquired its lock and is about to start monitoring.
+const readyMsg = "READY"
+
+// errMsg is the prefix written to the readiness pipe when the daemon fails
+// to start (e.g. another instance already holds the lock).
+const errMsg = "ERROR"
+
+// daemonReadyTimeout bounds how long Start waits for the re-exec'd child to
+// report readiness before giving up.
+const daemonReadyTimeout = 5 * time.Second
+
+// daemonStopGrace bounds how long Stop waits after SIGTERM before escalating
+// to SIGKILL.
+const daemonStopGrace = 5 * time.Second
+
+// pidFile returns the path to the flock-guarded pid file.
+func (m *Manager) pidFile() string {
+	return filepath.Join(m.rig.Path, ".gastown", "witness.pid")
+}
+
+// logFile returns the path to the daemon's log file.
+func (m *Manager) logFile() string {
+	return filepath.Join(m.rig.Path, ".gastown", "witness.log")
+}
+
+// startBackground re-execs the current binary as a detached daemon and
+// waits for it to report readiness before returning.
+func (m *Manager) startBackground(w *Witness) error {
+	dir := filepath.Dir(m.stateFile())
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	exe := m.testExe
+	if exe == "" {
+		var err error
+		exe, err = os.Executable()
+		if err != nil {
+			return fmt.Errorf("resolving executable for re-exec: %w", err)
+		}
+	}
+
+	args := []string{"witness", "__daemon", "--rig", m.rig.Path}
+	if m.testArgs != nil {
+		args = m.testArgs(m.rig.Path)
+	}
+
+	log, err := os.OpenFile(m.logFile(), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("opening witness log: %w", err)
+	}
+	defer log.Close()
+
+	readR, readW, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("creating readiness pipe: %w", err)
+	}
+	defer readR.Close()
+
+	cmd := exec.Command(exe, args...)
+	cmd.Dir = m.workDir
+	cmd.Stdin = nil
+	cmd.Stdout = log
+	cmd.Stderr = log
+	cmd.ExtraFiles = []*os.File{readW}
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if len(m.testEnv) > 0 {
+		cmd.Env = append(os.Environ(), m.testEnv...)
+	}
+
+	if err := cmd.Start(); err != nil {
+		readW.Close()
+		return fmt.Errorf("spawning witness daemon: %w", err)
+	}
+	// The child has its own copy of the write end; close ours so reads on
+	// readR observe EOF if the child exits without writing anything.
+	readW.Close()
+
+	// Daemonize: we're still the child's parent (Setsid only detaches the
+	// session, not the process tree), so something has to reap it or it
+	// lingers as a zombie for as long as this process runs. Wait on it in
+	// the background instead of releasing it outright.
+	go cmd.Wait()
+
+	line, err := readLineWithTimeout(readR, daemonReadyTimeout)
+	if err != nil {
+		return fmt.Errorf("witness daemon did not become ready: %w", err)
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return fmt.Errorf("witness daemon sent empty readiness message")
+	}
+
+	switch fields[0] {
+	case errMsg:
+		return fmt.Errorf("witness daemon failed to start: %s", strings.TrimSpace(strings.TrimPrefix(line, errMsg)))
+	case readyMsg:
+		if len(fields) < 2 {
+			return fmt.Errorf("witness daemon readiness message missing pid")
+		}
+		pid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return fmt.Errorf("witness daemon readiness message has invalid pid: %w", err)
+		}
+		w.PID = pid
+		w.State = StateRunning
+		now := time.Now()
+		w.StartedAt = &now
+		w.MonitoredPolecats = m.rig.Polecats
+		return m.saveState(w)
+	default:
+		return fmt.Errorf("witness daemon sent unrecognized readiness message: %q", line)
+	}
+}
+
+// readLineWithTimeout reads a single newline-terminated line from r, giving
+// up if nothing arrives within timeout.
+func readLineWithTimeout(r *os.File, timeout time.Duration) (string, error) {
+	type result struct {
+		line string
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		reader := bufio.NewReader(r)
+		line, err := reader.ReadString('\n')
+		ch <- result{line, err}
+	}()
+
+	select {
+	case res := <-ch:
+		if res.err != nil && res.line == "" {
+			return "", res.err
+		}
+		return res.line, nil
+	case <-time.After(timeout):
+		return "", fmt.Errorf("timed out after %s", timeout)
+	}
+}
+
+// DaemonMain is the body of the daemon process that startBackground
+// re-execs with the arguments "witness __daemon --rig <path>". It acquires
+// the single-instance lock, signals readiness to the parent over readyFD,
+// and then runs the monitoring loop in the foreground until it receives
+// SIGTERM/SIGINT.
+//
+// readyFD is the write end of the pipe the parent created via ExtraFiles;
+// as the first (and only) extra file it is fd 3 in the child.
+func DaemonMain(r *rig.Rig, readyFD uintptr) error {
+	m := NewManager(r)
+	ready := os.NewFile(readyFD, "witness-ready")
+	defer ready.Close()
+
+	lockFile, err := os.OpenFile(m.pidFile(), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		fmt.Fprintf(ready, "%s acquiring pid file: %v\n", errMsg, err)
+		return err
+	}
+	defer lockFile.Close()
+
+	if err := syscall.Flock(int(lockFile.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		fmt.Fprintf(ready, "%s another witness daemon is already running for this rig\n", errMsg)
+		return ErrAlreadyRunning
+	}
+	// Holding the flock for the lifetime of this process is the real
+	// single-instance guard; it's released automatically if we die.
+	defer syscall.Flock(int(lockFile.Fd()), syscall.LOCK_UN)
+
+	pid := os.Getpid()
+	lockFile.Truncate(0)
+	lockFile.Seek(0, 0)
+	fmt.Fprintf(lockFile, "%d\n", pid)
+
+	w, err := m.loadState()
+	if err != nil {
+		fmt.Fprintf(ready, "%s loading state: %v\n", errMsg, err)
+		return err
+	}
+	w.State = StateRunning
+	w.PID = pid
+	now := time.Now()
+	w.StartedAt = &now
+	w.MonitoredPolecats = m.rig.Polecats
+	if err := m.saveState(w); err != nil {
+		fmt.Fprintf(ready, "%s saving state: %v\n", errMsg, err)
+		return err
+	}
+
+	fmt.Fprintf(ready, "%s %d\n", readyMsg, pid)
+	ready.Close()
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	done := make(chan struct{})
+	go func() {
+		<-sigCh
+		done <- struct{}{}
+	}()
+
+	return m.runUntil(w, done)
+}
+
+// Logs returns the last n lines of the witness log file.
+func (m *Manager) Logs(n int) ([]string, error) {
+	data, err := os.ReadFile(m.logFile())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return nil, nil
+	}
+	if n <= 0 || n >= len(lines) {
+		return lines, nil
+	}
+	return lines[len(lines)-n:], nil
+}
+
+// stopBackground signals the daemon process to shut down and waits for it
+// to exit, escalating to SIGKILL if it doesn't within the grace period.
+func (m *Manager) stopBackground(pid int) error {
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return nil
+	}
+	if err := proc.Signal(syscall.SIGTERM); err != nil {
+		// Already gone.
+		return nil
+	}
+
+	deadline := time.Now().Add(daemonStopGrace)
+	for time.Now().Before(deadline) {
+		if !processExists(pid) {
+			return nil
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if processExists(pid) {
+		proc.Signal(syscall.SIGKILL)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package witness
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// TestHelperProcess is not a real test. It's re-exec'd by
+// TestStartBackgroundDaemonizes as a fake "gt witness __daemon" target so
+// the daemonization path can be exercised without a real gt binary.
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GT_WITNESS_HELPER") != "1" {
+		return
+	}
+
+	rigPath := ""
+	for i, arg := range os.Args {
+		if arg == "--rig" && i+1 < len(os.Args) {
+			rigPath = os.Args[i+1]
+		}
+	}
+	if rigPath == "" {
+		os.Exit(2)
+	}
+
+	r := &rig.Rig{Path: rigPath, Name: "test-rig"}
+	if err := DaemonMain(r, 3); err != nil {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func TestStartBackgroundDaemonizes(t *testing.T) {
+	dir := t.TempDir()
+	r := &rig.Rig{Path: dir, Name: "test-rig"}
+	m := NewManager(r)
+	m.testExe = os.Args[0]
+	m.testArgs = func(rigPath string) []string {
+		return []string{"-test.run=TestHelperProcess", "--", "--rig", rigPath}
+	}
+	m.testEnv = []string{"GT_WITNESS_HELPER=1"}
+
+	w, err := m.loadState()
+	if err != nil {
+		t.Fatalf("loadState: %v", err)
+	}
+
+	if err := m.startBackground(w); err != nil {
+		t.Fatalf("startBackground: %v", err)
+	}
+	defer m.stopBackground(w.PID)
+
+	if w.PID == 0 {
+		t.Fatal("expected startBackground to record a real child PID")
+	}
+	if w.PID == os.Getpid() {
+		t.Fatal("daemon PID should not be this test process's own PID")
+	}
+	if !processExists(w.PID) {
+		t.Fatalf("daemon process %d does not appear to be running", w.PID)
+	}
+
+	saved, err := m.loadState()
+	if err != nil {
+		t.Fatalf("loadState after start: %v", err)
+	}
+	if saved.State != StateRunning {
+		t.Fatalf("saved state = %q, want %q", saved.State, StateRunning)
+	}
+	if saved.PID != w.PID {
+		t.Fatalf("saved PID = %d, want %d", saved.PID, w.PID)
+	}
+}
+
+func TestStopBackgroundTerminatesDaemon(t *testing.T) {
+	dir := t.TempDir()
+	r := &rig.Rig{Path: dir, Name: "test-rig"}
+	m := NewManager(r)
+	m.testExe = os.Args[0]
+	m.testArgs = func(rigPath string) []string {
+		return []string{"-test.run=TestHelperProcess", "--", "--rig", rigPath}
+	}
+	m.testEnv = []string{"GT_WITNESS_HELPER=1"}
+
+	w, err := m.loadState()
+	if err != nil {
+		t.Fatalf("loadState: %v", err)
+	}
+	if err := m.startBackground(w); err != nil {
+		t.Fatalf("startBackground: %v", err)
+	}
+
+	if err := m.stopBackground(w.PID); err != nil {
+		t.Fatalf("stopBackground: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if !processExists(w.PID) {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("daemon process %d still running after stopBackground", w.PID)
+}
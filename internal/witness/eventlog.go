@@ -0,0 +1,201 @@
+package witness
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Tuning knobs for event-log rotation.
+const (
+	defaultEventLogMaxSize    = 10 * 1024 * 1024 // 10 MiB
+	defaultEventLogMaxBackups = 5
+)
+
+// EventLog is an append-only JSONL log of witness events, rotated once it
+// grows past a configurable size.
+type EventLog struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// NewEventLog creates an event log at path. maxSize <= 0 uses the default
+// (10 MiB); maxBackups <= 0 uses the default (5 rotated files).
+func NewEventLog(path string, maxSize int64, maxBackups int) *EventLog {
+	if maxSize <= 0 {
+		maxSize = defaultEventLogMaxSize
+	}
+	if maxBackups <= 0 {
+		maxBackups = defaultEventLogMaxBackups
+	}
+	return &EventLog{path: path, maxSize: maxSize, maxBackups: maxBackups}
+}
+
+// ensureOpen opens the log file if it isn't already, recording its current
+// size so rotation can be triggered at the right point.
+func (l *EventLog) ensureOpen() error {
+	if l.f != nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	l.f = f
+	l.size = info.Size()
+	return nil
+}
+
+// Write appends e to the log as a single JSON line, rotating first if the
+// write would push the log past maxSize.
+func (l *EventLog) Write(e Event) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := l.ensureOpen(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+
+	if l.size+int64(len(data)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := l.f.Write(data)
+	l.size += int64(n)
+	return err
+}
+
+// rotate closes the current log, shifts existing backups up by one
+// (dropping the oldest once maxBackups is exceeded), and opens a fresh log
+// file in its place.
+func (l *EventLog) rotate() error {
+	if err := l.f.Close(); err != nil {
+		return err
+	}
+	l.f = nil
+
+	for i := l.maxBackups - 1; i >= 1; i-- {
+		src := fmt.Sprintf("%s.%d", l.path, i)
+		dst := fmt.Sprintf("%s.%d", l.path, i+1)
+		if _, err := os.Stat(src); err == nil {
+			if err := os.Rename(src, dst); err != nil {
+				return err
+			}
+		}
+	}
+	if err := os.Rename(l.path, l.path+".1"); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return l.ensureOpen()
+}
+
+// Close closes the underlying log file, if open.
+func (l *EventLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.f == nil {
+		return nil
+	}
+	err := l.f.Close()
+	l.f = nil
+	return err
+}
+
+// EventReader tails a witness JSONL event log for consumption by tests and
+// UI tools. It does not follow across rotation; callers that need to
+// survive rotation should re-create the reader after detecting one.
+type EventReader struct {
+	path string
+}
+
+// NewEventReader creates a reader for the event log at path.
+func NewEventReader(path string) *EventReader {
+	return &EventReader{path: path}
+}
+
+// Tail streams events from the start of the log. If follow is true, it
+// keeps polling for newly appended events until ctx is canceled; otherwise
+// it stops at EOF. The returned error channel receives at most one error
+// and is then closed along with the events channel.
+func (r *EventReader) Tail(ctx context.Context, follow bool) (<-chan Event, <-chan error) {
+	events := make(chan Event)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(events)
+		defer close(errs)
+
+		f, err := os.Open(r.path)
+		if err != nil {
+			errs <- err
+			return
+		}
+		defer f.Close()
+
+		const pollInterval = 200 * time.Millisecond
+		reader := bufio.NewReader(f)
+
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				var e Event
+				if jerr := json.Unmarshal(line, &e); jerr == nil {
+					select {
+					case events <- e:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+
+			if err != nil {
+				if err != io.EOF {
+					errs <- err
+					return
+				}
+				if !follow {
+					return
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(pollInterval):
+				}
+			}
+		}
+	}()
+
+	return events, errs
+}
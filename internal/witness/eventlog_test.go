@@ -0,0 +1,118 @@
+package witness
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEventLogWriteAndRead(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "witness-events.log")
+
+	log := NewEventLog(path, 0, 0)
+	defer log.Close()
+
+	want := Event{Time: time.Unix(1700000000, 0).UTC(), Rig: "test-rig", Polecat: "gus", Kind: EventNudge, Reason: "idle"}
+	if err := log.Write(want); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if len(data) == 0 {
+		t.Fatal("expected event log to contain data")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	events, errs := NewEventReader(path).Tail(ctx, false)
+
+	var got []Event
+	for e := range events {
+		got = append(got, e)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("Tail: %v", err)
+	}
+
+	if len(got) != 1 {
+		t.Fatalf("got %d events, want 1", len(got))
+	}
+	if got[0].Polecat != want.Polecat || got[0].Kind != want.Kind || got[0].Reason != want.Reason {
+		t.Fatalf("got %+v, want %+v", got[0], want)
+	}
+}
+
+func TestEventLogRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "witness-events.log")
+
+	// A tiny max size forces rotation on nearly every write.
+	log := NewEventLog(path, 64, 2)
+	defer log.Close()
+
+	for i := 0; i < 10; i++ {
+		if err := log.Write(Event{Time: time.Unix(int64(i), 0).UTC(), Kind: EventCheck}); err != nil {
+			t.Fatalf("Write %d: %v", i, err)
+		}
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected current log to exist: %v", err)
+	}
+	if _, err := os.Stat(path + ".1"); err != nil {
+		t.Fatalf("expected at least one rotated backup: %v", err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("ReadDir: %v", err)
+	}
+	// The current log plus at most maxBackups (2) rotated files.
+	if len(entries) > 3 {
+		t.Fatalf("got %d log files, want at most 3", len(entries))
+	}
+}
+
+func TestEventReaderFollow(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "witness-events.log")
+
+	log := NewEventLog(path, 0, 0)
+	defer log.Close()
+	if err := log.Write(Event{Time: time.Unix(1, 0).UTC(), Kind: EventCheck}); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	events, _ := NewEventReader(path).Tail(ctx, true)
+
+	select {
+	case e := <-events:
+		if e.Kind != EventCheck {
+			t.Fatalf("Kind = %q, want %q", e.Kind, EventCheck)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for first event")
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		log.Write(Event{Time: time.Unix(2, 0).UTC(), Kind: EventNudge})
+	}()
+
+	select {
+	case e := <-events:
+		if e.Kind != EventNudge {
+			t.Fatalf("Kind = %q, want %q", e.Kind, EventNudge)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for followed event")
+	}
+}
@@ -0,0 +1,290 @@
+package witness
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CheckResult is what a Checker reports for a single polecat.
+type CheckResult struct {
+	Status CheckStatus
+	Reason string
+}
+
+// Checker determines the health of a single polecat.
+type Checker interface {
+	Check(ctx context.Context, polecat string) (CheckResult, error)
+}
+
+// Nudger delivers a prod to an idle or stuck polecat.
+type Nudger interface {
+	Nudge(ctx context.Context, polecat, reason string) error
+}
+
+// Escalator notifies the mayor that a polecat needs attention.
+type Escalator interface {
+	Escalate(ctx context.Context, polecat, reason string) error
+}
+
+// Tuning knobs for the health-check state machine.
+const (
+	defaultWorkerPoolSize = 4
+
+	defaultNudgeBaseBackoff = 1 * time.Minute
+	defaultMaxNudgeBackoff  = 30 * time.Minute
+
+	// defaultMaxNudgesBeforeEscalate gates escalation for a merely Idle
+	// polecat: several unanswered nudges before we bother the mayor.
+	defaultMaxNudgesBeforeEscalate = 3
+
+	// defaultMaxNudgesBeforeEscalateStuck is the equivalent gate for a
+	// Stuck polecat (keepalive far staler than Idle's threshold) - stuck
+	// is a stronger signal than idle, so it escalates much sooner.
+	defaultMaxNudgesBeforeEscalateStuck = 1
+
+	defaultEscalationBaseBackoff = 10 * time.Minute
+	defaultMaxEscalationBackoff  = 2 * time.Hour
+
+	defaultKeepaliveStale = 5 * time.Minute
+)
+
+// DefaultChecker reports health by reading a keepalive file each polecat is
+// expected to touch periodically: .gastown/polecats/<name>/keepalive.
+type DefaultChecker struct {
+	// RigDir is the rig's working directory (contains .gastown).
+	RigDir string
+
+	// StaleAfter is how long since the keepalive's mtime before a
+	// polecat is considered idle.
+	StaleAfter time.Duration
+
+	// now is overridable by tests; defaults to time.Now.
+	now func() time.Time
+}
+
+// NewDefaultChecker creates a checker that reads keepalive files under rigDir.
+func NewDefaultChecker(rigDir string, staleAfter time.Duration) *DefaultChecker {
+	if staleAfter <= 0 {
+		staleAfter = defaultKeepaliveStale
+	}
+	return &DefaultChecker{RigDir: rigDir, StaleAfter: staleAfter, now: time.Now}
+}
+
+func (c *DefaultChecker) keepaliveFile(polecat string) string {
+	return filepath.Join(c.RigDir, ".gastown", "polecats", polecat, "keepalive")
+}
+
+// Check implements Checker.
+func (c *DefaultChecker) Check(ctx context.Context, polecat string) (CheckResult, error) {
+	now := time.Now
+	if c.now != nil {
+		now = c.now
+	}
+
+	info, err := os.Stat(c.keepaliveFile(polecat))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return CheckResult{Status: Dead, Reason: "no keepalive file"}, nil
+		}
+		return CheckResult{}, err
+	}
+
+	age := now().Sub(info.ModTime())
+	switch {
+	case age <= c.StaleAfter:
+		return CheckResult{Status: Healthy}, nil
+	case age <= 3*c.StaleAfter:
+		return CheckResult{Status: Idle, Reason: fmt.Sprintf("keepalive is %s old", age.Round(time.Second))}, nil
+	default:
+		return CheckResult{Status: Stuck, Reason: fmt.Sprintf("keepalive is %s old", age.Round(time.Second))}, nil
+	}
+}
+
+// LogNudger is the default Nudger: it just logs. Real delivery (e.g. over
+// gt mail) is expected to be wired in by the caller.
+type LogNudger struct{}
+
+// Nudge implements Nudger.
+func (LogNudger) Nudge(ctx context.Context, polecat, reason string) error {
+	fmt.Printf("witness: nudging %s: %s\n", polecat, reason)
+	return nil
+}
+
+// LogEscalator is the default Escalator: it just logs. Real mayor
+// notification is expected to be wired in by the caller.
+type LogEscalator struct{}
+
+// Escalate implements Escalator.
+func (LogEscalator) Escalate(ctx context.Context, polecat, reason string) error {
+	fmt.Printf("witness: escalating %s to mayor: %s\n", polecat, reason)
+	return nil
+}
+
+// nudgeBackoff returns how long to wait before the next nudge, given how
+// many consecutive nudges have already been sent.
+func nudgeBackoff(nudgeCount int) time.Duration {
+	backoff := defaultNudgeBaseBackoff
+	for i := 0; i < nudgeCount; i++ {
+		backoff *= 2
+		if backoff >= defaultMaxNudgeBackoff {
+			return defaultMaxNudgeBackoff
+		}
+	}
+	return backoff
+}
+
+// escalationBackoff returns how long to wait before re-escalating the same
+// polecat, given how many times it's already been escalated. Without this,
+// a polecat that stays stuck past the nudge threshold gets re-escalated to
+// the mayor on every health-check tick forever.
+func escalationBackoff(escalationCount int) time.Duration {
+	backoff := defaultEscalationBaseBackoff
+	for i := 0; i < escalationCount; i++ {
+		backoff *= 2
+		if backoff >= defaultMaxEscalationBackoff {
+			return defaultMaxEscalationBackoff
+		}
+	}
+	return backoff
+}
+
+// healthCheck performs a health check on all monitored polecats, running
+// checks concurrently with a bounded worker pool, then applies the
+// idle/nudge/escalate state machine and persists the result.
+func (m *Manager) healthCheck(w *Witness) error {
+	now := m.clock()
+	w.LastCheckAt = &now
+	w.Stats.TotalChecks++
+	w.Stats.TodayChecks++
+
+	if w.PolecatHealth == nil {
+		w.PolecatHealth = make(map[string]*PolecatHealth)
+	}
+
+	checker := m.checker
+	if checker == nil {
+		checker = NewDefaultChecker(m.workDir, defaultKeepaliveStale)
+	}
+	nudger := m.nudger
+	if nudger == nil {
+		nudger = LogNudger{}
+	}
+	escalator := m.escalator
+	if escalator == nil {
+		escalator = LogEscalator{}
+	}
+	poolSize := m.workerPoolSize
+	if poolSize <= 0 {
+		poolSize = defaultWorkerPoolSize
+	}
+
+	ctx := context.Background()
+	sem := make(chan struct{}, poolSize)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+
+	for _, polecat := range m.rig.Polecats {
+		polecat := polecat
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result, err := checker.Check(ctx, polecat)
+			if err != nil {
+				result = CheckResult{Status: Idle, Reason: fmt.Sprintf("check error: %v", err)}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			m.applyCheckResult(w, polecat, result, nudger, escalator)
+		}()
+	}
+	wg.Wait()
+
+	m.publishEvent(Event{Time: now, Kind: EventCheck})
+	return m.saveState(w)
+}
+
+// applyCheckResult runs the per-polecat state machine: healthy resets the
+// nudge count, idle/stuck nudges (with exponential backoff) and escalates
+// once too many nudges have gone unanswered, and dead deletes the polecat's
+// health entry. A dead polecat stays in the rig's polecat list, so later
+// health checks still check it.
+func (m *Manager) applyCheckResult(w *Witness, polecat string, result CheckResult, nudger Nudger, escalator Escalator) {
+	ph, ok := w.PolecatHealth[polecat]
+	if !ok {
+		ph = &PolecatHealth{}
+		w.PolecatHealth[polecat] = ph
+	}
+
+	now := m.clock()
+	ph.LastCheckAt = &now
+	ph.Status = result.Status
+	ph.Reason = result.Reason
+
+	switch result.Status {
+	case Healthy:
+		ph.NudgeCount = 0
+		ph.EscalationCount = 0
+		return
+	case Dead:
+		// Remove from the active set; nothing further to do for it.
+		delete(w.PolecatHealth, polecat)
+		return
+	}
+
+	// Idle or Stuck. Stuck is a stronger signal than Idle, so it tolerates
+	// far fewer unanswered nudges before escalating.
+	maxNudges := m.maxNudgesBeforeEscalate
+	if maxNudges <= 0 {
+		maxNudges = defaultMaxNudgesBeforeEscalate
+	}
+	if result.Status == Stuck {
+		maxNudges = m.maxNudgesBeforeEscalateStuck
+		if maxNudges <= 0 {
+			maxNudges = defaultMaxNudgesBeforeEscalateStuck
+		}
+	}
+
+	if ph.NudgeCount >= maxNudges {
+		due := ph.LastEscalationAt == nil || now.Sub(*ph.LastEscalationAt) >= escalationBackoff(ph.EscalationCount)
+		if !due {
+			return
+		}
+
+		ctx := context.Background()
+		m.beginWork()
+		err := escalator.Escalate(ctx, polecat, result.Reason)
+		m.endWork()
+		if err == nil {
+			ph.EscalationCount++
+			ph.LastEscalationAt = &now
+			w.Stats.TotalEscalations++
+			m.publishEvent(Event{Time: now, Polecat: polecat, Kind: EventEscalate, Reason: result.Reason})
+		}
+		return
+	}
+
+	due := ph.LastNudgeAt == nil || now.Sub(*ph.LastNudgeAt) >= nudgeBackoff(ph.NudgeCount)
+	if !due {
+		return
+	}
+
+	ctx := context.Background()
+	m.beginWork()
+	err := nudger.Nudge(ctx, polecat, result.Reason)
+	m.endWork()
+	if err == nil {
+		ph.NudgeCount++
+		ph.LastNudgeAt = &now
+		w.Stats.TotalNudges++
+		w.Stats.TodayNudges++
+		m.publishEvent(Event{Time: now, Polecat: polecat, Kind: EventNudge, Reason: result.Reason})
+	}
+}
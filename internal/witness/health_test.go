@@ -0,0 +1,286 @@
+package witness
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+// writeKeepalive writes a keepalive file for polecat under dir, backdating
+// its mtime to mtime.
+func writeKeepalive(t *testing.T, dir, polecat string, mtime time.Time) {
+	t.Helper()
+	path := filepath.Join(dir, ".gastown", "polecats", polecat, "keepalive")
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("MkdirAll: %v", err)
+	}
+	if err := os.WriteFile(path, []byte("ok"), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+}
+
+// fakeChecker returns a fixed result for every polecat, regardless of name.
+type fakeChecker struct {
+	result CheckResult
+}
+
+func (f fakeChecker) Check(ctx context.Context, polecat string) (CheckResult, error) {
+	return f.result, nil
+}
+
+// recordingNudgerEscalator counts how many times it was invoked.
+type recordingNudgerEscalator struct {
+	nudges      int
+	escalations int
+}
+
+func (r *recordingNudgerEscalator) Nudge(ctx context.Context, polecat, reason string) error {
+	r.nudges++
+	return nil
+}
+
+func (r *recordingNudgerEscalator) Escalate(ctx context.Context, polecat, reason string) error {
+	r.escalations++
+	return nil
+}
+
+func newTestManager(t *testing.T, polecats []string) (*Manager, *time.Time) {
+	t.Helper()
+	dir := t.TempDir()
+	r := &rig.Rig{Path: dir, Name: "test-rig", Polecats: polecats}
+	m := NewManager(r)
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	m.clockFn = func() time.Time { return now }
+	return m, &now
+}
+
+// phSeed describes the PolecatHealth entry (if any) to seed before a test
+// case runs. The lastNudgeAtNow/lastEscalationAtNow flags stamp that field
+// with the test's fake-clock start time, since backoff cases need a
+// relative-to-now timestamp rather than a fixed one.
+type phSeed struct {
+	exists              bool
+	status              CheckStatus
+	nudgeCount          int
+	escalationCount     int
+	lastNudgeAtNow      bool
+	lastEscalationAtNow bool
+}
+
+// applyCheckResultStep is one call to applyCheckResult within a test case,
+// after advancing the fake clock by advance. wantNudges/wantEscalations are
+// cumulative totals across all steps run so far in the case.
+type applyCheckResultStep struct {
+	advance         time.Duration
+	result          CheckResult
+	wantNudges      int
+	wantEscalations int
+}
+
+func TestApplyCheckResult(t *testing.T) {
+	tests := []struct {
+		name                         string
+		maxNudgesBeforeEscalate      int
+		maxNudgesBeforeEscalateStuck int
+		seed                         phSeed
+		steps                        []applyCheckResultStep
+		wantRemoved                  bool
+		wantNudgeCount               int
+		wantEscalationCount          int
+	}{
+		{
+			name: "healthy resets nudge count",
+			seed: phSeed{exists: true, status: Idle, nudgeCount: 2},
+			steps: []applyCheckResultStep{
+				{result: CheckResult{Status: Healthy}},
+			},
+			wantNudgeCount: 0,
+		},
+		{
+			name: "dead removes polecat",
+			seed: phSeed{exists: true, status: Healthy},
+			steps: []applyCheckResultStep{
+				{result: CheckResult{Status: Dead, Reason: "gone"}},
+			},
+			wantRemoved: true,
+		},
+		{
+			name: "idle nudges on first check",
+			steps: []applyCheckResultStep{
+				{result: CheckResult{Status: Idle, Reason: "quiet"}, wantNudges: 1},
+			},
+			wantNudgeCount: 1,
+		},
+		{
+			name: "idle honors nudge backoff",
+			seed: phSeed{exists: true, status: Idle, nudgeCount: 1, lastNudgeAtNow: true},
+			steps: []applyCheckResultStep{
+				// Backoff for NudgeCount=1 is 2 minutes; not yet elapsed.
+				{result: CheckResult{Status: Idle, Reason: "still quiet"}, wantNudges: 0},
+				{advance: 5 * time.Minute, result: CheckResult{Status: Idle, Reason: "still quiet"}, wantNudges: 1},
+			},
+			wantNudgeCount: 2,
+		},
+		{
+			name:                    "escalates after max nudges",
+			maxNudgesBeforeEscalate: 2,
+			seed:                    phSeed{exists: true, status: Stuck, nudgeCount: 2},
+			steps: []applyCheckResultStep{
+				{result: CheckResult{Status: Stuck, Reason: "unresponsive"}, wantEscalations: 1},
+			},
+			wantNudgeCount:      2,
+			wantEscalationCount: 1,
+		},
+		{
+			name:                    "does not re-escalate within backoff",
+			maxNudgesBeforeEscalate: 2,
+			seed:                    phSeed{exists: true, status: Idle, nudgeCount: 2, escalationCount: 1, lastEscalationAtNow: true},
+			steps: []applyCheckResultStep{
+				{result: CheckResult{Status: Idle, Reason: "still quiet"}, wantEscalations: 0},
+				{advance: defaultEscalationBaseBackoff * 2, result: CheckResult{Status: Idle, Reason: "still quiet"}, wantEscalations: 1},
+			},
+			wantNudgeCount:      2,
+			wantEscalationCount: 2,
+		},
+		{
+			name:                         "stuck escalates sooner than idle",
+			maxNudgesBeforeEscalate:      3,
+			maxNudgesBeforeEscalateStuck: 1,
+			seed:                         phSeed{exists: true, status: Stuck, nudgeCount: 1},
+			steps: []applyCheckResultStep{
+				{result: CheckResult{Status: Stuck, Reason: "very stale"}, wantEscalations: 1},
+			},
+			wantNudgeCount:      1,
+			wantEscalationCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, now := newTestManager(t, []string{"gus"})
+			m.maxNudgesBeforeEscalate = tt.maxNudgesBeforeEscalate
+			m.maxNudgesBeforeEscalateStuck = tt.maxNudgesBeforeEscalateStuck
+
+			w := &Witness{PolecatHealth: map[string]*PolecatHealth{}}
+			if tt.seed.exists {
+				ph := &PolecatHealth{Status: tt.seed.status, NudgeCount: tt.seed.nudgeCount, EscalationCount: tt.seed.escalationCount}
+				if tt.seed.lastNudgeAtNow {
+					t := *now
+					ph.LastNudgeAt = &t
+				}
+				if tt.seed.lastEscalationAtNow {
+					t := *now
+					ph.LastEscalationAt = &t
+				}
+				w.PolecatHealth["gus"] = ph
+			}
+			ne := &recordingNudgerEscalator{}
+
+			for _, step := range tt.steps {
+				*now = now.Add(step.advance)
+				m.applyCheckResult(w, "gus", step.result, ne, ne)
+
+				if ne.nudges != step.wantNudges {
+					t.Errorf("after step %+v: nudges = %d, want %d", step.result, ne.nudges, step.wantNudges)
+				}
+				if ne.escalations != step.wantEscalations {
+					t.Errorf("after step %+v: escalations = %d, want %d", step.result, ne.escalations, step.wantEscalations)
+				}
+			}
+
+			ph, ok := w.PolecatHealth["gus"]
+			if tt.wantRemoved {
+				if ok {
+					t.Error("expected polecat to be removed from PolecatHealth")
+				}
+				return
+			}
+			if !ok {
+				t.Fatal("expected polecat to remain in PolecatHealth")
+			}
+			if ph.NudgeCount != tt.wantNudgeCount {
+				t.Errorf("NudgeCount = %d, want %d", ph.NudgeCount, tt.wantNudgeCount)
+			}
+			if ph.EscalationCount != tt.wantEscalationCount {
+				t.Errorf("EscalationCount = %d, want %d", ph.EscalationCount, tt.wantEscalationCount)
+			}
+			if w.Stats.TotalNudges != ne.nudges {
+				t.Errorf("Stats.TotalNudges = %d, want %d", w.Stats.TotalNudges, ne.nudges)
+			}
+			if w.Stats.TotalEscalations != ne.escalations {
+				t.Errorf("Stats.TotalEscalations = %d, want %d", w.Stats.TotalEscalations, ne.escalations)
+			}
+		})
+	}
+}
+
+func TestHealthCheckRunsAllPolecatsConcurrently(t *testing.T) {
+	m, _ := newTestManager(t, []string{"gus", "toast", "fang"})
+	m.checker = fakeChecker{result: CheckResult{Status: Healthy}}
+	ne := &recordingNudgerEscalator{}
+	m.nudger = ne
+	m.escalator = ne
+
+	w := &Witness{}
+	if err := m.healthCheck(w); err != nil {
+		t.Fatalf("healthCheck: %v", err)
+	}
+
+	if len(w.PolecatHealth) != 3 {
+		t.Errorf("len(PolecatHealth) = %d, want 3", len(w.PolecatHealth))
+	}
+	if w.Stats.TotalChecks != 1 {
+		t.Errorf("TotalChecks = %d, want 1", w.Stats.TotalChecks)
+	}
+}
+
+func TestDefaultCheckerStatuses(t *testing.T) {
+	dir := t.TempDir()
+	c := NewDefaultChecker(dir, time.Minute)
+	fixedNow := time.Date(2026, 1, 1, 0, 10, 0, 0, time.UTC)
+	c.now = func() time.Time { return fixedNow }
+
+	// No keepalive file at all: dead.
+	result, err := c.Check(context.Background(), "ghost")
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if result.Status != Dead {
+		t.Errorf("Status = %q, want %q", result.Status, Dead)
+	}
+
+	writeKeepalive(t, dir, "fresh", fixedNow.Add(-30*time.Second))
+	result, err = c.Check(context.Background(), "fresh")
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if result.Status != Healthy {
+		t.Errorf("Status = %q, want %q", result.Status, Healthy)
+	}
+
+	writeKeepalive(t, dir, "quiet", fixedNow.Add(-2*time.Minute))
+	result, err = c.Check(context.Background(), "quiet")
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if result.Status != Idle {
+		t.Errorf("Status = %q, want %q", result.Status, Idle)
+	}
+
+	writeKeepalive(t, dir, "stale", fixedNow.Add(-10*time.Minute))
+	result, err = c.Check(context.Background(), "stale")
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if result.Status != Stuck {
+		t.Errorf("Status = %q, want %q", result.Status, Stuck)
+	}
+}
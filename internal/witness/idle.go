@@ -0,0 +1,72 @@
+package witness
+
+import "context"
+
+// beginWork marks one unit of async work (a health check, a nudge send, or
+// an escalation) as started. Every call must be paired with endWork.
+func (m *Manager) beginWork() {
+	m.busyMu.Lock()
+	m.busyCount++
+	m.busyMu.Unlock()
+}
+
+// endWork marks a unit of work started by beginWork as finished. Once the
+// last in-flight unit completes, it wakes any WaitIdle callers and fires
+// any pending OnIdle callbacks.
+func (m *Manager) endWork() {
+	m.busyMu.Lock()
+	m.busyCount--
+	var callbacks []func()
+	if m.busyCount == 0 {
+		callbacks = m.idleCallbacks
+		m.idleCallbacks = nil
+		m.busyCond.Broadcast()
+	}
+	m.busyMu.Unlock()
+
+	for _, fn := range callbacks {
+		fn()
+	}
+}
+
+// WaitIdle blocks until the witness has completed any in-flight health
+// checks, drained any queued nudges, and has no pending escalations, or
+// until ctx is done. It's meant to let integration tests and `gt witness
+// stop --drain` synchronize with the daemon deterministically instead of
+// sleeping.
+//
+// If ctx is done before the witness goes idle, the background goroutine
+// waiting on busyCond isn't interrupted; it exits harmlessly once the
+// in-flight work it was waiting on completes and broadcasts.
+func (m *Manager) WaitIdle(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		m.busyMu.Lock()
+		for m.busyCount > 0 {
+			m.busyCond.Wait()
+		}
+		m.busyMu.Unlock()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// OnIdle registers fn to run the next time the witness becomes idle. If
+// the witness is already idle, fn runs immediately (synchronously, on the
+// calling goroutine).
+func (m *Manager) OnIdle(fn func()) {
+	m.busyMu.Lock()
+	if m.busyCount == 0 {
+		m.busyMu.Unlock()
+		fn()
+		return
+	}
+	m.idleCallbacks = append(m.idleCallbacks, fn)
+	m.busyMu.Unlock()
+}
@@ -0,0 +1,124 @@
+package witness
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/steveyegge/gastown/internal/rig"
+)
+
+func TestWaitIdleReturnsImmediatelyWhenIdle(t *testing.T) {
+	r := &rig.Rig{Path: t.TempDir(), Name: "test-rig"}
+	m := NewManager(r)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := m.WaitIdle(ctx); err != nil {
+		t.Fatalf("WaitIdle on an idle manager: %v", err)
+	}
+}
+
+func TestWaitIdleBlocksUntilWorkCompletes(t *testing.T) {
+	r := &rig.Rig{Path: t.TempDir(), Name: "test-rig"}
+	m := NewManager(r)
+
+	m.beginWork()
+
+	done := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+		done <- m.WaitIdle(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("WaitIdle returned early (err=%v) while work was still in flight", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	m.endWork()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("WaitIdle: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("WaitIdle did not return after the in-flight work completed")
+	}
+}
+
+func TestWaitIdleRespectsContextCancellation(t *testing.T) {
+	r := &rig.Rig{Path: t.TempDir(), Name: "test-rig"}
+	m := NewManager(r)
+
+	m.beginWork()
+	defer m.endWork()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := m.WaitIdle(ctx); err == nil {
+		t.Fatal("expected WaitIdle to return an error once its context expired")
+	}
+}
+
+func TestOnIdleFiresImmediatelyWhenAlreadyIdle(t *testing.T) {
+	r := &rig.Rig{Path: t.TempDir(), Name: "test-rig"}
+	m := NewManager(r)
+
+	called := false
+	m.OnIdle(func() { called = true })
+	if !called {
+		t.Fatal("expected OnIdle to fire immediately when already idle")
+	}
+}
+
+func TestOnIdleFiresOnceWorkCompletes(t *testing.T) {
+	r := &rig.Rig{Path: t.TempDir(), Name: "test-rig"}
+	m := NewManager(r)
+
+	m.beginWork()
+
+	fired := make(chan struct{})
+	m.OnIdle(func() { close(fired) })
+
+	select {
+	case <-fired:
+		t.Fatal("OnIdle fired before the in-flight work completed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	m.endWork()
+
+	select {
+	case <-fired:
+	case <-time.After(time.Second):
+		t.Fatal("OnIdle did not fire after the in-flight work completed")
+	}
+}
+
+func TestBeginEndWorkNesting(t *testing.T) {
+	r := &rig.Rig{Path: t.TempDir(), Name: "test-rig"}
+	m := NewManager(r)
+
+	m.beginWork()
+	m.beginWork()
+	m.endWork()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := m.WaitIdle(ctx); err == nil {
+		t.Fatal("expected to still be busy with one unit of work outstanding")
+	}
+
+	m.endWork()
+
+	ctx2, cancel2 := context.WithTimeout(context.Background(), time.Second)
+	defer cancel2()
+	if err := m.WaitIdle(ctx2); err != nil {
+		t.Fatalf("WaitIdle after all work completed: %v", err)
+	}
+}
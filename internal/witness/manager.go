@@ -5,10 +5,17 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/steveyegge/gastown/internal/rig"
+	"github.com/steveyegge/gastown/internal/version"
 )
 
 // Common errors
@@ -21,14 +28,93 @@ var (
 type Manager struct {
 	rig     *rig.Rig
 	workDir string
+
+	// testExe and testArgs let tests substitute a fake re-exec target
+	// instead of the real gt binary. Unset in production.
+	testExe  string
+	testArgs func(rigPath string) []string
+	testEnv  []string
+
+	// checker, nudger, and escalator back the health-check subsystem.
+	// Nil means "use the default". Tests override these directly.
+	checker                      Checker
+	nudger                       Nudger
+	escalator                    Escalator
+	workerPoolSize               int
+	maxNudgesBeforeEscalate      int
+	maxNudgesBeforeEscalateStuck int
+
+	// clockFn is overridable by tests; nil means time.Now.
+	clockFn func() time.Time
+
+	// stateMu guards w against concurrent access from the control-socket
+	// HTTP handlers while the monitoring loop mutates it.
+	stateMu sync.RWMutex
+
+	// eventMu guards eventSubs.
+	eventMu   sync.Mutex
+	eventSubs map[chan Event]struct{}
+
+	// logMu guards eventLog's lazy initialization.
+	logMu    sync.Mutex
+	eventLog *EventLog
+
+	// eventLogMaxSize and eventLogMaxBackups tune event-log rotation. Zero
+	// means "use the default". Tests override these directly.
+	eventLogMaxSize    int64
+	eventLogMaxBackups int
+
+	// metrics tracks the Prometheus-style counters and gauges served over
+	// the control socket's /metrics endpoint.
+	metrics *Metrics
+
+	// busyMu guards busyCount, busyCond, and idleCallbacks: the
+	// busy/idle bookkeeping that backs WaitIdle, OnIdle, and /idle.
+	busyMu        sync.Mutex
+	busyCond      *sync.Cond
+	busyCount     int
+	idleCallbacks []func()
+
+	// staleBinaryNotified guards against re-publishing EventStaleBin on
+	// every tick once the running binary has been observed stale; it's
+	// only ever set, never cleared, since a running process's own binary
+	// can't un-stale itself.
+	staleBinaryNotified bool
+}
+
+// clock returns the current time, or the injected fake clock in tests.
+func (m *Manager) clock() time.Time {
+	if m.clockFn != nil {
+		return m.clockFn()
+	}
+	return time.Now()
 }
 
 // NewManager creates a new witness manager for a rig.
 func NewManager(r *rig.Rig) *Manager {
-	return &Manager{
+	m := &Manager{
 		rig:     r,
 		workDir: r.Path,
+		metrics: newMetrics(),
+	}
+	m.busyCond = sync.NewCond(&m.busyMu)
+	return m
+}
+
+// eventLogPath returns the path to the witness's append-only JSONL event
+// log.
+func (m *Manager) eventLogPath() string {
+	return filepath.Join(m.rig.Path, ".gastown", "witness-events.log")
+}
+
+// getEventLog returns the witness's event log, opening it on first use.
+func (m *Manager) getEventLog() *EventLog {
+	m.logMu.Lock()
+	defer m.logMu.Unlock()
+	if m.eventLog == nil {
+		m.eventLog = NewEventLog(m.eventLogPath(), m.eventLogMaxSize, m.eventLogMaxBackups)
 	}
+	return m.eventLog
 }
 
 // stateFile returns the path to the witness state file.
@@ -79,12 +165,17 @@ func (m *Manager) Status() (*Witness, error) {
 		return nil, err
 	}
 
-	// If running, verify process is still alive
+	// If running, verify process is still alive and still actually our witness
+	// (the PID could have been recycled by an unrelated process).
 	if w.State == StateRunning && w.PID > 0 {
 		if !processExists(w.PID) {
 			w.State = StateStopped
 			w.PID = 0
 			m.saveState(w)
+		} else if !processIsWitness(w.PID) {
+			w.State = StateStopped
+			w.PID = 0
+			m.saveState(w)
 		}
 	}
 
@@ -107,24 +198,25 @@ func (m *Manager) Start(foreground bool) error {
 		return ErrAlreadyRunning
 	}
 
+	if !foreground {
+		// Background mode: re-exec as a detached daemon and wait for it to
+		// report readiness before returning. The daemon itself records its
+		// real PID once it's up.
+		return m.startBackground(w)
+	}
+
 	now := time.Now()
 	w.State = StateRunning
 	w.StartedAt = &now
-	w.PID = os.Getpid() // For foreground mode; background would set actual PID
+	w.PID = os.Getpid()
 	w.MonitoredPolecats = m.rig.Polecats
 
 	if err := m.saveState(w); err != nil {
 		return err
 	}
 
-	if foreground {
-		// Run the monitoring loop (blocking)
-		return m.run(w)
-	}
-
-	// Background mode: spawn a new process
-	// For MVP, we just mark as running - actual daemon implementation later
-	return nil
+	// Run the monitoring loop (blocking)
+	return m.run(w)
 }
 
 // Stop stops the witness.
@@ -140,9 +232,8 @@ func (m *Manager) Stop() error {
 
 	// If we have a PID, try to stop it gracefully
 	if w.PID > 0 && w.PID != os.Getpid() {
-		// Send SIGTERM
-		if proc, err := os.FindProcess(w.PID); err == nil {
-			proc.Signal(os.Interrupt)
+		if err := m.stopBackground(w.PID); err != nil {
+			return err
 		}
 	}
 
@@ -152,36 +243,95 @@ func (m *Manager) Stop() error {
 	return m.saveState(w)
 }
 
-// run is the main monitoring loop (for foreground mode).
+// run is the main monitoring loop (for foreground mode). It traps
+// SIGINT/SIGTERM itself, mirroring DaemonMain, so that a foreground witness
+// runs its deferred cleanup (closing the control socket, publishing
+// EventStop) instead of dying to the Go runtime's default signal
+// disposition.
 func (m *Manager) run(w *Witness) error {
 	fmt.Println("Witness running...")
 	fmt.Println("Press Ctrl+C to stop")
 
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+	done := make(chan struct{})
+	go func() {
+		<-sigCh
+		done <- struct{}{}
+	}()
+
+	return m.runUntil(w, done)
+}
+
+// runUntil runs the monitoring loop until stop is closed or receives a
+// value. A nil stop channel means "run forever": the loop then ends only
+// when the process itself is killed.
+func (m *Manager) runUntil(w *Witness, stop <-chan struct{}) error {
+	stopAPI, err := m.startAPIServer(w)
+	if err != nil {
+		fmt.Printf("witness: control socket unavailable: %v\n", err)
+	} else {
+		defer stopAPI()
+	}
+
+	m.publishEvent(Event{Time: m.clock(), Kind: EventStart})
+
 	ticker := time.NewTicker(30 * time.Second)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			// Perform health check
-			if err := m.healthCheck(w); err != nil {
+			m.stateMu.Lock()
+			paused := w.State == StatePaused
+			var err error
+			if !paused {
+				m.beginWork()
+				err = m.healthCheck(w)
+				m.checkStaleBinary()
+				m.endWork()
+			}
+			m.stateMu.Unlock()
+			if err != nil {
 				fmt.Printf("Health check error: %v\n", err)
 			}
+		case <-stop:
+			m.stateMu.Lock()
+			w.State = StateStopped
+			w.PID = 0
+			err := m.saveState(w)
+			m.stateMu.Unlock()
+			m.publishEvent(Event{Time: m.clock(), Kind: EventStop})
+			return err
 		}
 	}
 }
 
-// healthCheck performs a health check on all monitored polecats.
-func (m *Manager) healthCheck(w *Witness) error {
-	now := time.Now()
-	w.LastCheckAt = &now
-	w.Stats.TotalChecks++
-	w.Stats.TodayChecks++
-
-	// For MVP, just update state
-	// Future: check keepalive files, nudge idle polecats, escalate stuck ones
-
-	return m.saveState(w)
+// checkStaleBinary reports (via EventStaleBin) the first time
+// version.CheckStaleBinary finds the running witness binary stale against
+// the rig's repo HEAD: built from an older or diverged commit, or from a
+// dirty tree. It's checked on every tick but only published once per process
+// lifetime: a running binary's own staleness can't improve on its own, so
+// re-publishing every 30s would just flood the event log.
+func (m *Manager) checkStaleBinary() {
+	if m.staleBinaryNotified {
+		return
+	}
+	info := version.CheckStaleBinary(m.rig.Path)
+	if info.Error != nil || !info.IsStale {
+		return
+	}
+	m.staleBinaryNotified = true
+	reason := "witness binary is stale relative to repo HEAD"
+	if info.CommitsBehind > 0 {
+		reason = fmt.Sprintf("witness binary is %d commit(s) behind repo HEAD", info.CommitsBehind)
+	}
+	m.publishEvent(Event{
+		Time:   m.clock(),
+		Kind:   EventStaleBin,
+		Reason: reason,
+	})
 }
 
 // processExists checks if a process with the given PID exists.
@@ -191,6 +341,28 @@ func processExists(pid int) bool {
 		return false
 	}
 	// On Unix, FindProcess always succeeds; signal 0 tests existence
-	err = proc.Signal(nil)
+	// without actually signaling the process. A nil os.Signal fails the
+	// type assertion inside os.Process.Signal, so it must be an explicit
+	// syscall.Signal(0).
+	err = proc.Signal(syscall.Signal(0))
 	return err == nil
 }
+
+// processIsWitness reports whether pid looks like one of our witness
+// daemons, distinguishing "PID alive but reused by an unrelated process"
+// from a genuine (possibly stale) witness. Best-effort: on platforms
+// without /proc, or if the cmdline can't be read, it assumes the PID is
+// still ours rather than false-alarming a healthy daemon as dead.
+func processIsWitness(pid int) bool {
+	if runtime.GOOS != "linux" {
+		return true
+	}
+
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "cmdline"))
+	if err != nil {
+		return true
+	}
+
+	cmdline := strings.ReplaceAll(string(data), "\x00", " ")
+	return strings.Contains(cmdline, "witness")
+}
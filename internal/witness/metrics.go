@@ -0,0 +1,93 @@
+package witness
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Metrics holds in-memory Prometheus-style counters and gauges for a
+// witness instance, updated as events are published and served over the
+// control socket's /metrics endpoint.
+type Metrics struct {
+	mu               sync.Mutex
+	checksTotal      uint64
+	nudgesTotal      map[string]uint64
+	escalationsTotal map[string]uint64
+	lastCheckTS      int64
+	up               int
+}
+
+// newMetrics creates an empty Metrics.
+func newMetrics() *Metrics {
+	return &Metrics{
+		nudgesTotal:      make(map[string]uint64),
+		escalationsTotal: make(map[string]uint64),
+	}
+}
+
+// record updates the counters/gauges affected by e.
+func (me *Metrics) record(e Event) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	switch e.Kind {
+	case EventCheck:
+		me.checksTotal++
+		me.lastCheckTS = e.Time.Unix()
+	case EventNudge:
+		me.nudgesTotal[e.Polecat]++
+	case EventEscalate:
+		me.escalationsTotal[e.Polecat]++
+	case EventStart:
+		me.up = 1
+	case EventStop:
+		me.up = 0
+	}
+}
+
+// WriteTo renders the current metrics in Prometheus text exposition
+// format.
+func (me *Metrics) WriteTo(w io.Writer) (int64, error) {
+	me.mu.Lock()
+	defer me.mu.Unlock()
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "# HELP witness_checks_total Total number of health checks performed.\n")
+	fmt.Fprintf(&buf, "# TYPE witness_checks_total counter\n")
+	fmt.Fprintf(&buf, "witness_checks_total %d\n", me.checksTotal)
+
+	fmt.Fprintf(&buf, "# HELP witness_nudges_total Total number of nudges sent, per polecat.\n")
+	fmt.Fprintf(&buf, "# TYPE witness_nudges_total counter\n")
+	for _, polecat := range sortedKeys(me.nudgesTotal) {
+		fmt.Fprintf(&buf, "witness_nudges_total{polecat=%q} %d\n", polecat, me.nudgesTotal[polecat])
+	}
+
+	fmt.Fprintf(&buf, "# HELP witness_escalations_total Total number of escalations to the mayor, per polecat.\n")
+	fmt.Fprintf(&buf, "# TYPE witness_escalations_total counter\n")
+	for _, polecat := range sortedKeys(me.escalationsTotal) {
+		fmt.Fprintf(&buf, "witness_escalations_total{polecat=%q} %d\n", polecat, me.escalationsTotal[polecat])
+	}
+
+	fmt.Fprintf(&buf, "# HELP witness_last_check_timestamp_seconds Unix timestamp of the last health check.\n")
+	fmt.Fprintf(&buf, "# TYPE witness_last_check_timestamp_seconds gauge\n")
+	fmt.Fprintf(&buf, "witness_last_check_timestamp_seconds %d\n", me.lastCheckTS)
+
+	fmt.Fprintf(&buf, "# HELP witness_up Whether the witness control server is running.\n")
+	fmt.Fprintf(&buf, "# TYPE witness_up gauge\n")
+	fmt.Fprintf(&buf, "witness_up %d\n", me.up)
+
+	return buf.WriteTo(w)
+}
+
+func sortedKeys(m map[string]uint64) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,53 @@
+package witness
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsRecordAndWriteTo(t *testing.T) {
+	m := newMetrics()
+
+	m.record(Event{Time: time.Unix(1700000000, 0).UTC(), Kind: EventCheck})
+	m.record(Event{Kind: EventNudge, Polecat: "gus"})
+	m.record(Event{Kind: EventNudge, Polecat: "gus"})
+	m.record(Event{Kind: EventEscalate, Polecat: "gus"})
+	m.record(Event{Kind: EventStart})
+
+	var buf strings.Builder
+	if _, err := m.WriteTo(&buf); err != nil {
+		t.Fatalf("WriteTo: %v", err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"witness_checks_total 1",
+		`witness_nudges_total{polecat="gus"} 2`,
+		`witness_escalations_total{polecat="gus"} 1`,
+		"witness_last_check_timestamp_seconds 1700000000",
+		"witness_up 1",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q\ngot:\n%s", want, out)
+		}
+	}
+}
+
+func TestMetricsUpTogglesOnStartStop(t *testing.T) {
+	m := newMetrics()
+
+	m.record(Event{Kind: EventStart})
+	var buf strings.Builder
+	m.WriteTo(&buf)
+	if !strings.Contains(buf.String(), "witness_up 1") {
+		t.Fatal("expected witness_up 1 after start")
+	}
+
+	m.record(Event{Kind: EventStop})
+	buf.Reset()
+	m.WriteTo(&buf)
+	if !strings.Contains(buf.String(), "witness_up 0") {
+		t.Fatal("expected witness_up 0 after stop")
+	}
+}
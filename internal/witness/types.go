@@ -41,6 +41,57 @@ type Witness struct {
 
 	// Stats contains cumulative statistics.
 	Stats WitnessStats `json:"stats"`
+
+	// PolecatHealth tracks per-polecat health-check state, keyed by
+	// polecat name.
+	PolecatHealth map[string]*PolecatHealth `json:"polecat_health,omitempty"`
+}
+
+// CheckStatus is the result of a single health check on a polecat.
+type CheckStatus string
+
+const (
+	// Healthy means the polecat is actively working.
+	Healthy CheckStatus = "healthy"
+
+	// Idle means the polecat hasn't shown activity recently but isn't
+	// considered stuck yet.
+	Idle CheckStatus = "idle"
+
+	// Stuck means the polecat has been inactive far longer than the Idle
+	// threshold; it is escalated after fewer unanswered nudges than Idle.
+	Stuck CheckStatus = "stuck"
+
+	// Dead means the polecat is no longer running and should be dropped
+	// from the active monitoring set.
+	Dead CheckStatus = "dead"
+)
+
+// PolecatHealth is the persisted health-check history for one polecat.
+type PolecatHealth struct {
+	// Status is the result of the most recent check.
+	Status CheckStatus `json:"status"`
+
+	// Reason is the human-readable explanation for Status.
+	Reason string `json:"reason,omitempty"`
+
+	// LastCheckAt is when this polecat was last checked.
+	LastCheckAt *time.Time `json:"last_check_at,omitempty"`
+
+	// LastNudgeAt is when this polecat was last nudged.
+	LastNudgeAt *time.Time `json:"last_nudge_at,omitempty"`
+
+	// LastEscalationAt is when this polecat was last escalated to the
+	// mayor.
+	LastEscalationAt *time.Time `json:"last_escalation_at,omitempty"`
+
+	// NudgeCount is the number of consecutive nudges sent since the
+	// polecat was last seen healthy.
+	NudgeCount int `json:"nudge_count"`
+
+	// EscalationCount is the number of times this polecat has been
+	// escalated to the mayor since it was last seen healthy.
+	EscalationCount int `json:"escalation_count"`
 }
 
 // WitnessStats contains cumulative witness statistics.